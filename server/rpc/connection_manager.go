@@ -0,0 +1,164 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+// BalancerPolicy selects the gRPC client-side load balancing policy used for
+// a target. Name is the balancer name as registered with balancer.Register;
+// custom balancers are referenced by passing their registered name here.
+type BalancerPolicy string
+
+const (
+	// BalancerRoundRobin distributes RPCs evenly across all addresses the
+	// resolver returns for a target.
+	BalancerRoundRobin BalancerPolicy = "round_robin"
+	// BalancerPickFirst sends all RPCs to the first address that connects,
+	// only failing over if it goes down.
+	BalancerPickFirst BalancerPolicy = "pick_first"
+)
+
+// ConnectionManagerConfig controls how ConnectionManager dials and caches
+// connections for a target.
+type ConnectionManagerConfig struct {
+	// BalancerPolicy selects the load balancing policy. Defaults to
+	// BalancerRoundRobin when empty.
+	BalancerPolicy BalancerPolicy `yaml:"balancerPolicy"`
+	// HealthCheckServiceName, when non-empty, enables gRPC client-side health
+	// checking against the named service so unhealthy Frontend replicas are
+	// ejected from the balancer's address list automatically.
+	HealthCheckServiceName string `yaml:"healthCheckServiceName"`
+}
+
+// applyTo overlays cfg's balancer policy and health-check settings onto
+// baseServiceConfigJSON (falling back to defaultServiceConfigJSON when empty),
+// preserving whatever methodConfig that base document already carries -
+// including the retry/hedging policies defaultServiceConfigJSON builds, or an
+// operator's WithServiceConfigOverride - rather than replacing it outright.
+func (cfg ConnectionManagerConfig) applyTo(baseServiceConfigJSON string) (string, error) {
+	if baseServiceConfigJSON == "" {
+		baseServiceConfigJSON = defaultServiceConfigJSON()
+	}
+
+	// Decode into raw JSON values rather than serviceConfig so that keys this
+	// package doesn't model (e.g. a methodConfig's timeout) survive the merge.
+	var sc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(baseServiceConfigJSON), &sc); err != nil {
+		return "", fmt.Errorf("failed to parse base service config: %w", err)
+	}
+	if sc == nil {
+		sc = make(map[string]json.RawMessage)
+	}
+
+	policy := cfg.BalancerPolicy
+	if policy == "" {
+		policy = BalancerRoundRobin
+	}
+	// Marshaling these fixed shapes cannot fail.
+	sc["loadBalancingConfig"], _ = json.Marshal([]map[string]struct{}{{string(policy): {}}})
+
+	if cfg.HealthCheckServiceName != "" {
+		sc["healthCheckConfig"], _ = json.Marshal(healthCheckConfig{ServiceName: cfg.HealthCheckServiceName})
+	}
+
+	b, err := json.Marshal(sc)
+	if err != nil {
+		return "", fmt.Errorf("failed to render merged service config: %w", err)
+	}
+	return string(b), nil
+}
+
+// ConnectionManager dials and caches gRPC connections to Temporal services,
+// replacing the package-level CreateGRPCConnection helper that swallowed
+// dial errors and couldn't reuse connections across targets.
+type ConnectionManager struct {
+	factory *RPCFactory
+	config  ConnectionManagerConfig
+
+	mu    sync.Mutex
+	conns map[string]*grpc.ClientConn
+}
+
+// NewConnectionManager builds a ConnectionManager that dials connections
+// using factory's TLS, ConnectParams, keepalive, and interceptor settings.
+func NewConnectionManager(factory *RPCFactory, config ConnectionManagerConfig) *ConnectionManager {
+	return &ConnectionManager{
+		factory: factory,
+		config:  config,
+		conns:   make(map[string]*grpc.ClientConn),
+	}
+}
+
+// CreateFrontendGRPCConnection returns a cached connection to the Frontend at
+// target, dialing one if it doesn't already exist. target may use any scheme
+// gRPC's resolver registry supports, e.g. "dns:///frontend.temporal:7233" or
+// "xds:///frontend.temporal".
+func (m *ConnectionManager) CreateFrontendGRPCConnection(target string) (*grpc.ClientConn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if conn, ok := m.conns[target]; ok {
+		return conn, nil
+	}
+
+	tlsConfig, err := m.factory.GetTLSConfigFor(target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS config for %q: %w", target, err)
+	}
+
+	cfg, unaryInterceptors, streamInterceptors := m.factory.snapshot()
+	mergedServiceConfig, err := m.config.applyTo(cfg.ServiceConfigJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build service config for %q: %w", target, err)
+	}
+	cfg.ServiceConfigJSON = mergedServiceConfig
+
+	conn, err := Dial(target, tlsConfig, cfg, unaryInterceptors, streamInterceptors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gRPC connection to %q: %w", target, err)
+	}
+
+	m.conns[target] = conn
+	return conn, nil
+}
+
+// Close closes all cached connections and clears the cache.
+func (m *ConnectionManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var firstErr error
+	for target, conn := range m.conns {
+		if err := conn.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close connection to %q: %w", target, err)
+		}
+		delete(m.conns, target)
+	}
+	return firstErr
+}
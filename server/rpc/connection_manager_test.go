@@ -0,0 +1,85 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConnectionManagerConfigApplyToPreservesMethodConfig(t *testing.T) {
+	cfg := ConnectionManagerConfig{
+		BalancerPolicy:         BalancerPickFirst,
+		HealthCheckServiceName: "frontend",
+	}
+
+	merged, err := cfg.applyTo(defaultServiceConfigJSON())
+	if err != nil {
+		t.Fatalf("applyTo returned an error: %v", err)
+	}
+
+	var sc serviceConfig
+	if err := json.Unmarshal([]byte(merged), &sc); err != nil {
+		t.Fatalf("applyTo produced invalid JSON: %v", err)
+	}
+
+	if len(sc.MethodConfig) != 2 {
+		t.Fatalf("expected the base document's 2 methodConfig entries to survive, got %d", len(sc.MethodConfig))
+	}
+	if sc.MethodConfig[0].RetryPolicy == nil {
+		t.Error("expected retryPolicy from the base service config to be preserved")
+	}
+	if sc.MethodConfig[1].HedgingPolicy == nil {
+		t.Error("expected hedgingPolicy from the base service config to be preserved")
+	}
+
+	if len(sc.LoadBalancingConfig) != 1 {
+		t.Fatalf("expected 1 loadBalancingConfig entry, got %d", len(sc.LoadBalancingConfig))
+	}
+	if _, ok := sc.LoadBalancingConfig[0][string(BalancerPickFirst)]; !ok {
+		t.Errorf("expected loadBalancingConfig to be overridden to %q, got %v", BalancerPickFirst, sc.LoadBalancingConfig[0])
+	}
+
+	if sc.HealthCheckConfig == nil || sc.HealthCheckConfig.ServiceName != "frontend" {
+		t.Errorf("expected healthCheckConfig.serviceName %q, got %+v", "frontend", sc.HealthCheckConfig)
+	}
+}
+
+func TestConnectionManagerConfigApplyToDefaultsToRoundRobin(t *testing.T) {
+	merged, err := (ConnectionManagerConfig{}).applyTo("")
+	if err != nil {
+		t.Fatalf("applyTo returned an error: %v", err)
+	}
+
+	var sc serviceConfig
+	if err := json.Unmarshal([]byte(merged), &sc); err != nil {
+		t.Fatalf("applyTo produced invalid JSON: %v", err)
+	}
+
+	if _, ok := sc.LoadBalancingConfig[0][string(BalancerRoundRobin)]; !ok {
+		t.Errorf("expected empty BalancerPolicy to default to %q, got %v", BalancerRoundRobin, sc.LoadBalancingConfig[0])
+	}
+	if sc.HealthCheckConfig != nil {
+		t.Errorf("expected no healthCheckConfig when HealthCheckServiceName is unset, got %+v", sc.HealthCheckConfig)
+	}
+}
@@ -0,0 +1,109 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// httpRequestContextKey is the context key under which the incoming HTTP
+// request is stashed so authTokenUnaryInterceptor can read its Authorization
+// header and forward it to the Frontend.
+type httpRequestContextKey struct{}
+
+// WithIncomingHTTPRequest attaches the incoming HTTP request to ctx so that
+// built-in interceptors (authTokenUnaryInterceptor) can propagate headers
+// such as Authorization to the outgoing gRPC call.
+func WithIncomingHTTPRequest(ctx context.Context, r *http.Request) context.Context {
+	return context.WithValue(ctx, httpRequestContextKey{}, r)
+}
+
+// RegisterUnaryInterceptor appends a unary client interceptor to the chain
+// installed by Dial. Interceptors run in the order they were registered,
+// with the built-in error interceptor innermost (closest to the wire).
+func (d *RPCFactory) RegisterUnaryInterceptor(interceptor grpc.UnaryClientInterceptor) {
+	d.Lock()
+	defer d.Unlock()
+	d.unaryInterceptors = append(d.unaryInterceptors, interceptor)
+}
+
+// RegisterStreamInterceptor appends a stream client interceptor to the chain
+// installed by Dial. Long-poll history streams rely on this to carry the
+// same tracing/metrics/auth behavior as unary calls.
+func (d *RPCFactory) RegisterStreamInterceptor(interceptor grpc.StreamClientInterceptor) {
+	d.Lock()
+	defer d.Unlock()
+	d.streamInterceptors = append(d.streamInterceptors, interceptor)
+}
+
+// RegisterDefaultInterceptors wires up the built-in OpenTelemetry tracing,
+// Prometheus client-side metrics, and Authorization-header propagation
+// interceptors. Callers that want tracing or metrics alone can register
+// otelgrpc.UnaryClientInterceptor/StreamClientInterceptor or
+// grpcprometheus.UnaryClientInterceptor/StreamClientInterceptor directly
+// instead of calling this; Authorization-header propagation is only
+// available as a whole via this method, since authTokenUnaryInterceptor and
+// authTokenStreamInterceptor are unexported.
+func (d *RPCFactory) RegisterDefaultInterceptors() {
+	d.RegisterUnaryInterceptor(otelgrpc.UnaryClientInterceptor())
+	d.RegisterStreamInterceptor(otelgrpc.StreamClientInterceptor())
+	// EnableClientHandlingTimeHistogram turns on the per-method latency
+	// histogram; without it grpcprometheus only exports started/handled
+	// counters and the interceptors below record no latency at all.
+	grpcprometheus.EnableClientHandlingTimeHistogram()
+	d.RegisterUnaryInterceptor(grpcprometheus.UnaryClientInterceptor)
+	d.RegisterStreamInterceptor(grpcprometheus.StreamClientInterceptor)
+	d.RegisterUnaryInterceptor(authTokenUnaryInterceptor)
+	d.RegisterStreamInterceptor(authTokenStreamInterceptor)
+}
+
+// authTokenUnaryInterceptor forwards the Authorization header of the
+// incoming HTTP request (see WithIncomingHTTPRequest) as outgoing gRPC
+// metadata, so the operator's SSO token reaches the Frontend.
+func authTokenUnaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withAuthToken(ctx), method, req, reply, cc, opts...)
+}
+
+// authTokenStreamInterceptor is the streaming counterpart of
+// authTokenUnaryInterceptor.
+func authTokenStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withAuthToken(ctx), desc, cc, method, opts...)
+}
+
+func withAuthToken(ctx context.Context) context.Context {
+	r, ok := ctx.Value(httpRequestContextKey{}).(*http.Request)
+	if !ok || r == nil {
+		return ctx
+	}
+	token := r.Header.Get("Authorization")
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", token)
+}
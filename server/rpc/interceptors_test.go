@@ -0,0 +1,90 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestWithAuthTokenForwardsAuthorizationHeader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer token123")
+
+	ctx := withAuthToken(WithIncomingHTTPRequest(context.Background(), r))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		t.Fatal("expected outgoing metadata to be set")
+	}
+	got := md.Get("authorization")
+	if len(got) != 1 || got[0] != "Bearer token123" {
+		t.Errorf("expected authorization metadata %q, got %v", "Bearer token123", got)
+	}
+}
+
+func TestWithAuthTokenNoopWithoutIncomingRequest(t *testing.T) {
+	ctx := withAuthToken(context.Background())
+
+	if _, ok := metadata.FromOutgoingContext(ctx); ok {
+		t.Error("expected no outgoing metadata when no HTTP request is attached")
+	}
+}
+
+func TestWithAuthTokenNoopWithoutAuthorizationHeader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	ctx := withAuthToken(WithIncomingHTTPRequest(context.Background(), r))
+
+	if _, ok := metadata.FromOutgoingContext(ctx); ok {
+		t.Error("expected no outgoing metadata when the request has no Authorization header")
+	}
+}
+
+func TestAuthTokenUnaryInterceptorForwardsContext(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer token123")
+
+	var sawToken string
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if md, ok := metadata.FromOutgoingContext(ctx); ok {
+			if v := md.Get("authorization"); len(v) == 1 {
+				sawToken = v[0]
+			}
+		}
+		return nil
+	}
+
+	ctx := WithIncomingHTTPRequest(context.Background(), r)
+	if err := authTokenUnaryInterceptor(ctx, "/service/Method", nil, nil, nil, invoker); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sawToken != "Bearer token123" {
+		t.Errorf("expected invoker to see forwarded token, got %q", sawToken)
+	}
+}
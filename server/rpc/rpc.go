@@ -25,17 +25,19 @@ package rpc
 import (
 	"context"
 	"crypto/tls"
-	"fmt"
 	"sync"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
 )
 
 const (
-	// DefaultServiceConfig is a default gRPC connection service config which enables DNS round robin between IPs.
+	// DefaultServiceConfig is a bare-bones gRPC service config which enables DNS round robin between IPs
+	// and nothing else. Dial no longer uses this itself (see defaultServiceConfigJSON, which adds retry
+	// and hedging policies); it's kept as a minimal starting point for WithServiceConfigOverride callers.
 	// To use DNS resolver, a "dns:///" prefix should be applied to the hostPort.
 	// https://github.com/grpc/grpc/blob/master/doc/naming.md
 	DefaultServiceConfig = `{"loadBalancingConfig": [{"round_robin":{}}]}`
@@ -45,21 +47,123 @@ const (
 
 	// minConnectTimeout is the minimum amount of time we are willing to give a connection to complete.
 	minConnectTimeout = 20 * time.Second
+
+	// defaultMaxRecvMsgSize is the default maximum size, in bytes, the client will accept for a single
+	// received message. Temporal's workflow histories can be large, so this is intentionally generous.
+	defaultMaxRecvMsgSize = 128 * 1024 * 1024
+
+	// defaultMaxSendMsgSize is the default maximum size, in bytes, the client will send in a single message.
+	defaultMaxSendMsgSize = 128 * 1024 * 1024
 )
 
+// ConnectParams mirrors grpc/backoff.Config and additionally carries the
+// connection-level MinConnectTimeout so it can be loaded from config as a
+// single unit rather than split across grpc.ConnectParams.
+type ConnectParams struct {
+	// BaseDelay is the amount of time to wait before retrying after the first failure.
+	BaseDelay time.Duration `yaml:"baseDelay"`
+	// Multiplier is the factor with which to multiply backoffs after a failed retry.
+	Multiplier float64 `yaml:"multiplier"`
+	// Jitter is the factor with which backoffs are randomized.
+	Jitter float64 `yaml:"jitter"`
+	// MaxDelay is the upper bound of backoff delay.
+	MaxDelay time.Duration `yaml:"maxDelay"`
+	// MinConnectTimeout is the minimum amount of time we are willing to give a connection to complete.
+	MinConnectTimeout time.Duration `yaml:"minConnectTimeout"`
+}
+
+// KeepAliveParams configures the gRPC client's keepalive pings, used to
+// detect a dead connection (e.g. a silently dropped load balancer) faster
+// than TCP alone would.
+type KeepAliveParams struct {
+	// Time is the interval after which, if the client doesn't see any activity, it pings the server.
+	Time time.Duration `yaml:"time"`
+	// Timeout is the amount of time the client waits for a ping ack before considering the connection dead.
+	Timeout time.Duration `yaml:"timeout"`
+	// PermitWithoutStream, if true, allows keepalive pings even when there are no active streams.
+	PermitWithoutStream bool `yaml:"permitWithoutStream"`
+}
+
+// Config controls the gRPC dial options used by RPCFactory. All fields are
+// optional; a zero value falls back to the package defaults that were
+// previously hard-coded.
+type Config struct {
+	// ConnectParams tunes the reconnection backoff strategy.
+	ConnectParams ConnectParams `yaml:"connectParams"`
+	// KeepAliveParams tunes client-side keepalive pings.
+	KeepAliveParams KeepAliveParams `yaml:"keepAliveParams"`
+	// MaxRecvMsgSize is the maximum message size in bytes the client will accept.
+	MaxRecvMsgSize int `yaml:"maxRecvMsgSize"`
+	// MaxSendMsgSize is the maximum message size in bytes the client will send.
+	MaxSendMsgSize int `yaml:"maxSendMsgSize"`
+	// ServiceConfigJSON, when non-empty, is used in place of defaultServiceConfigJSON
+	// as the gRPC service config passed to grpc.WithDefaultServiceConfig.
+	ServiceConfigJSON string `yaml:"serviceConfigJSON"`
+}
+
+// defaultConnectParams returns the ConnectParams this package used before it
+// was made configurable, preserved here so callers who don't set anything
+// keep today's behavior.
+func defaultConnectParams() ConnectParams {
+	return ConnectParams{
+		BaseDelay:         backoff.DefaultConfig.BaseDelay,
+		Multiplier:        backoff.DefaultConfig.Multiplier,
+		Jitter:            backoff.DefaultConfig.Jitter,
+		MaxDelay:          MaxBackoffDelay,
+		MinConnectTimeout: minConnectTimeout,
+	}
+}
+
+// withDefaults fills in any zero-valued fields of cfg with the package
+// defaults and returns the result; cfg itself is left untouched.
+//
+// KeepAliveParams is intentionally left as-is here: an unset KeepAliveParams.Time
+// means "keepalive pings disabled", matching this package's behavior before
+// keepalive became configurable. Dial enforces that by only installing
+// grpc.WithKeepaliveParams when Time > 0; don't backfill a default Time here.
+func (c Config) withDefaults() Config {
+	defaults := defaultConnectParams()
+	if c.ConnectParams.BaseDelay == 0 {
+		c.ConnectParams.BaseDelay = defaults.BaseDelay
+	}
+	if c.ConnectParams.Multiplier == 0 {
+		c.ConnectParams.Multiplier = defaults.Multiplier
+	}
+	if c.ConnectParams.Jitter == 0 {
+		c.ConnectParams.Jitter = defaults.Jitter
+	}
+	if c.ConnectParams.MaxDelay == 0 {
+		c.ConnectParams.MaxDelay = defaults.MaxDelay
+	}
+	if c.ConnectParams.MinConnectTimeout == 0 {
+		c.ConnectParams.MinConnectTimeout = defaults.MinConnectTimeout
+	}
+	if c.MaxRecvMsgSize == 0 {
+		c.MaxRecvMsgSize = defaultMaxRecvMsgSize
+	}
+	if c.MaxSendMsgSize == 0 {
+		c.MaxSendMsgSize = defaultMaxSendMsgSize
+	}
+	return c
+}
+
 // RPCFactory builds RPC & TLS config
 type RPCFactory struct {
 	Address string
+	Config  Config
 
 	sync.Mutex
-	tlsFactory TLSConfigProvider
+	tlsFactory         TLSConfigProvider
+	unaryInterceptors  []grpc.UnaryClientInterceptor
+	streamInterceptors []grpc.StreamClientInterceptor
 }
 
 // NewFactory builds a new RPCFactory
 // conforming to the underlying configuration
-func NewFactory(rpcAddress string, tlsProvider TLSConfigProvider) *RPCFactory {
+func NewFactory(rpcAddress string, tlsProvider TLSConfigProvider, cfg Config) *RPCFactory {
 	return &RPCFactory{
 		Address:    rpcAddress,
+		Config:     cfg,
 		tlsFactory: tlsProvider,
 	}
 }
@@ -72,47 +176,92 @@ func (d *RPCFactory) GetTLSConfig() (*tls.Config, error) {
 	return nil, nil
 }
 
-// CreateGRPCConnection creates connection for gRPC calls
-func CreateGRPCConnection(hostName string, tls *tls.Config) *grpc.ClientConn {
-	connection, err := Dial(hostName, tls)
-
+// Dial creates a client connection to the given target using this factory's
+// configured TLS, ConnectParams/keepalive/message-size settings, and
+// registered interceptor chain.
+func (d *RPCFactory) Dial(hostName string) (*grpc.ClientConn, error) {
+	tlsConfig, err := d.GetTLSConfigFor(hostName)
 	if err != nil {
-		fmt.Println("Failed to create gRPC connection")
+		return nil, err
 	}
+	cfg, unaryInterceptors, streamInterceptors := d.snapshot()
+	return Dial(hostName, tlsConfig, cfg, unaryInterceptors, streamInterceptors)
+}
 
-	return connection
+// snapshot returns a copy of d.Config and the registered interceptor chains,
+// taken under d's mutex in one critical section. Use this instead of reading
+// d.Config or d.unaryInterceptors/d.streamInterceptors directly: Config can
+// be mutated concurrently via WithServiceConfigOverride, and the interceptor
+// slices via RegisterUnaryInterceptor/RegisterStreamInterceptor.
+func (d *RPCFactory) snapshot() (Config, []grpc.UnaryClientInterceptor, []grpc.StreamClientInterceptor) {
+	d.Lock()
+	defer d.Unlock()
+	cfg := d.Config
+	unaryInterceptors := append([]grpc.UnaryClientInterceptor(nil), d.unaryInterceptors...)
+	streamInterceptors := append([]grpc.StreamClientInterceptor(nil), d.streamInterceptors...)
+	return cfg, unaryInterceptors, streamInterceptors
 }
 
 // Dial creates a client connection to the given target with default options.
 // The hostName syntax is defined in
 // https://github.com/grpc/grpc/blob/master/doc/naming.md.
 // e.g. to use dns resolver, a "dns:///" prefix should be applied to the target.
-func Dial(hostName string, tlsConfig *tls.Config) (*grpc.ClientConn, error) {
+func Dial(hostName string, tlsConfig *tls.Config, cfg Config, unaryInterceptors []grpc.UnaryClientInterceptor, streamInterceptors []grpc.StreamClientInterceptor) (*grpc.ClientConn, error) {
 	// Default to insecure
 	grpcSecureOpt := grpc.WithInsecure()
 	if tlsConfig != nil {
 		grpcSecureOpt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
 	}
 
+	cfg = cfg.withDefaults()
+
 	// gRPC maintains connection pool inside grpc.ClientConn.
 	// This connection pool has auto reconnect feature.
 	// If connection goes down, gRPC will try to reconnect using exponential backoff strategy:
 	// https://github.com/grpc/grpc/blob/master/doc/connection-backoff.md.
 	// Default MaxDelay is 120 seconds which is too high.
-	var cp = grpc.ConnectParams{
-		Backoff:           backoff.DefaultConfig,
-		MinConnectTimeout: minConnectTimeout,
+	cp := grpc.ConnectParams{
+		Backoff: backoff.Config{
+			BaseDelay:  cfg.ConnectParams.BaseDelay,
+			Multiplier: cfg.ConnectParams.Multiplier,
+			Jitter:     cfg.ConnectParams.Jitter,
+			MaxDelay:   cfg.ConnectParams.MaxDelay,
+		},
+		MinConnectTimeout: cfg.ConnectParams.MinConnectTimeout,
+	}
+
+	unaryChain := append(append([]grpc.UnaryClientInterceptor{}, unaryInterceptors...), errorInterceptor)
+	streamChain := append([]grpc.StreamClientInterceptor{}, streamInterceptors...)
+
+	serviceConfigJSON := cfg.ServiceConfigJSON
+	if serviceConfigJSON == "" {
+		serviceConfigJSON = defaultServiceConfigJSON()
 	}
-	cp.Backoff.MaxDelay = MaxBackoffDelay
 
-	return grpc.Dial(hostName,
+	opts := []grpc.DialOption{
 		grpcSecureOpt,
-		grpc.WithChainUnaryInterceptor(
-			errorInterceptor),
-		grpc.WithDefaultServiceConfig(DefaultServiceConfig),
-		grpc.WithDisableServiceConfig(),
+		grpc.WithChainUnaryInterceptor(unaryChain...),
+		grpc.WithChainStreamInterceptor(streamChain...),
+		grpc.WithDefaultServiceConfig(serviceConfigJSON),
 		grpc.WithConnectParams(cp),
-	)
+		grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(cfg.MaxRecvMsgSize),
+			grpc.MaxCallSendMsgSize(cfg.MaxSendMsgSize),
+		),
+	}
+
+	// Only enable keepalive pings when a positive Time is configured: an
+	// unset KeepAliveParams must not translate into pinging with no delay,
+	// which real Frontends will kill with ENHANCE_YOUR_CALM.
+	if cfg.KeepAliveParams.Time > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                cfg.KeepAliveParams.Time,
+			Timeout:             cfg.KeepAliveParams.Timeout,
+			PermitWithoutStream: cfg.KeepAliveParams.PermitWithoutStream,
+		}))
+	}
+
+	return grpc.Dial(hostName, opts...)
 }
 
 func errorInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
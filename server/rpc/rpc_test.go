@@ -0,0 +1,71 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigWithDefaultsFillsOnlyZeroFields(t *testing.T) {
+	cfg := Config{
+		ConnectParams: ConnectParams{
+			BaseDelay: 5 * time.Second,
+		},
+		MaxRecvMsgSize: 42,
+	}
+
+	got := cfg.withDefaults()
+
+	if got.ConnectParams.BaseDelay != 5*time.Second {
+		t.Errorf("expected explicit BaseDelay to survive, got %v", got.ConnectParams.BaseDelay)
+	}
+	if got.MaxRecvMsgSize != 42 {
+		t.Errorf("expected explicit MaxRecvMsgSize to survive, got %d", got.MaxRecvMsgSize)
+	}
+
+	defaults := defaultConnectParams()
+	if got.ConnectParams.Multiplier != defaults.Multiplier {
+		t.Errorf("expected unset Multiplier to default to %v, got %v", defaults.Multiplier, got.ConnectParams.Multiplier)
+	}
+	if got.ConnectParams.Jitter != defaults.Jitter {
+		t.Errorf("expected unset Jitter to default to %v, got %v", defaults.Jitter, got.ConnectParams.Jitter)
+	}
+	if got.ConnectParams.MaxDelay != defaults.MaxDelay {
+		t.Errorf("expected unset MaxDelay to default to %v, got %v", defaults.MaxDelay, got.ConnectParams.MaxDelay)
+	}
+	if got.ConnectParams.MinConnectTimeout != defaults.MinConnectTimeout {
+		t.Errorf("expected unset MinConnectTimeout to default to %v, got %v", defaults.MinConnectTimeout, got.ConnectParams.MinConnectTimeout)
+	}
+	if got.MaxSendMsgSize != defaultMaxSendMsgSize {
+		t.Errorf("expected unset MaxSendMsgSize to default to %d, got %d", defaultMaxSendMsgSize, got.MaxSendMsgSize)
+	}
+
+	if got.KeepAliveParams != (KeepAliveParams{}) {
+		t.Errorf("expected withDefaults to leave KeepAliveParams untouched (disabled), got %+v", got.KeepAliveParams)
+	}
+
+	if cfg.ConnectParams.Multiplier != 0 {
+		t.Error("withDefaults must not mutate the receiver")
+	}
+}
@@ -0,0 +1,151 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import "encoding/json"
+
+// workflowServiceName is the fully-qualified gRPC service name of Temporal's
+// WorkflowService, as it appears in a gRPC service config's MethodConfig.Name.
+const workflowServiceName = "temporal.api.workflowservice.v1.WorkflowService"
+
+// retryableWorkflowServiceMethods lists the read-only WorkflowService RPCs
+// that are safe to retry automatically: they have no side effects, so a
+// transient UNAVAILABLE/RESOURCE_EXHAUSTED from the Frontend can be retried
+// without risk of double-applying a mutation.
+var retryableWorkflowServiceMethods = []string{
+	"ListWorkflowExecutions",
+	"GetWorkflowExecutionHistory",
+	"DescribeWorkflowExecution",
+}
+
+// hedgedWorkflowServiceMethods lists latency-sensitive, idempotent
+// WorkflowService RPCs for which firing a second, hedged request without
+// waiting for the first to fail will usually improve tail latency more than
+// it costs in extra Frontend load.
+var hedgedWorkflowServiceMethods = []string{
+	"DescribeNamespace",
+}
+
+// methodName identifies a gRPC method within a MethodConfig entry.
+type methodName struct {
+	Service string `json:"service"`
+	Method  string `json:"method,omitempty"`
+}
+
+// retryPolicy is the JSON shape of a gRPC service config retryPolicy block.
+// See https://github.com/grpc/proposal/blob/master/A6-client-retries.md.
+type retryPolicy struct {
+	MaxAttempts          int      `json:"maxAttempts"`
+	InitialBackoff       string   `json:"initialBackoff"`
+	MaxBackoff           string   `json:"maxBackoff"`
+	BackoffMultiplier    float64  `json:"backoffMultiplier"`
+	RetryableStatusCodes []string `json:"retryableStatusCodes"`
+}
+
+// hedgingPolicy is the JSON shape of a gRPC service config hedgingPolicy
+// block. See https://github.com/grpc/proposal/blob/master/A17-hedging-rpcs.md.
+type hedgingPolicy struct {
+	MaxAttempts         int      `json:"maxAttempts"`
+	HedgingDelay        string   `json:"hedgingDelay"`
+	NonFatalStatusCodes []string `json:"nonFatalStatusCodes"`
+}
+
+// methodConfig is one entry of a gRPC service config's top-level
+// "methodConfig" array.
+type methodConfig struct {
+	Name          []methodName   `json:"name"`
+	RetryPolicy   *retryPolicy   `json:"retryPolicy,omitempty"`
+	HedgingPolicy *hedgingPolicy `json:"hedgingPolicy,omitempty"`
+}
+
+// healthCheckConfig is the JSON shape of a gRPC service config
+// healthCheckConfig block, which causes the client to eject addresses the
+// named service reports unhealthy from the balancer's pool.
+type healthCheckConfig struct {
+	ServiceName string `json:"serviceName"`
+}
+
+// serviceConfig is the JSON shape of a gRPC service config document. Only
+// the fields this package populates are modeled; see
+// https://github.com/grpc/grpc/blob/master/doc/service_config.md for the
+// full schema.
+type serviceConfig struct {
+	LoadBalancingConfig []map[string]struct{} `json:"loadBalancingConfig,omitempty"`
+	MethodConfig        []methodConfig        `json:"methodConfig,omitempty"`
+	HealthCheckConfig   *healthCheckConfig    `json:"healthCheckConfig,omitempty"`
+}
+
+// defaultServiceConfigJSON is the gRPC service config used when neither
+// Config.ServiceConfigJSON nor ConnectionManagerConfig override it: DNS
+// round-robin load balancing plus automatic retries for read-only
+// WorkflowService methods and hedging for DescribeNamespace, so transient
+// Frontend hiccups become invisible retries instead of 500s on the UI.
+func defaultServiceConfigJSON() string {
+	cfg := serviceConfig{
+		LoadBalancingConfig: []map[string]struct{}{
+			{string(BalancerRoundRobin): {}},
+		},
+		MethodConfig: []methodConfig{
+			{
+				Name: methodNamesFor(retryableWorkflowServiceMethods),
+				RetryPolicy: &retryPolicy{
+					MaxAttempts:          5,
+					InitialBackoff:       "0.1s",
+					MaxBackoff:           "10s",
+					BackoffMultiplier:    2,
+					RetryableStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+				},
+			},
+			{
+				Name: methodNamesFor(hedgedWorkflowServiceMethods),
+				HedgingPolicy: &hedgingPolicy{
+					MaxAttempts:         3,
+					HedgingDelay:        "0.1s",
+					NonFatalStatusCodes: []string{"UNAVAILABLE", "RESOURCE_EXHAUSTED"},
+				},
+			},
+		},
+	}
+
+	// This is a fixed, well-formed literal built from a static struct: it
+	// cannot fail to marshal.
+	b, _ := json.Marshal(cfg)
+	return string(b)
+}
+
+func methodNamesFor(methods []string) []methodName {
+	names := make([]methodName, len(methods))
+	for i, m := range methods {
+		names[i] = methodName{Service: workflowServiceName, Method: m}
+	}
+	return names
+}
+
+// WithServiceConfigOverride sets a raw gRPC service config JSON document to
+// use instead of defaultServiceConfigJSON, for operators who need retry,
+// hedging, or load-balancing behavior this package doesn't model directly.
+func (d *RPCFactory) WithServiceConfigOverride(json string) {
+	d.Lock()
+	defer d.Unlock()
+	d.Config.ServiceConfigJSON = json
+}
@@ -0,0 +1,91 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDefaultServiceConfigJSON(t *testing.T) {
+	var sc serviceConfig
+	if err := json.Unmarshal([]byte(defaultServiceConfigJSON()), &sc); err != nil {
+		t.Fatalf("defaultServiceConfigJSON produced invalid JSON: %v", err)
+	}
+
+	if len(sc.MethodConfig) != 2 {
+		t.Fatalf("expected 2 methodConfig entries, got %d", len(sc.MethodConfig))
+	}
+
+	retryEntry := sc.MethodConfig[0]
+	if retryEntry.RetryPolicy == nil {
+		t.Fatal("expected the first methodConfig entry to carry a retryPolicy")
+	}
+	if retryEntry.HedgingPolicy != nil {
+		t.Fatal("retry methodConfig entry should not also carry a hedgingPolicy")
+	}
+	for _, method := range retryableWorkflowServiceMethods {
+		if !containsMethod(retryEntry.Name, method) {
+			t.Errorf("retry methodConfig missing %q", method)
+		}
+	}
+
+	hedgeEntry := sc.MethodConfig[1]
+	if hedgeEntry.HedgingPolicy == nil {
+		t.Fatal("expected the second methodConfig entry to carry a hedgingPolicy")
+	}
+	for _, method := range hedgedWorkflowServiceMethods {
+		if !containsMethod(hedgeEntry.Name, method) {
+			t.Errorf("hedging methodConfig missing %q", method)
+		}
+	}
+
+	if len(sc.LoadBalancingConfig) != 1 {
+		t.Fatalf("expected 1 loadBalancingConfig entry, got %d", len(sc.LoadBalancingConfig))
+	}
+	if _, ok := sc.LoadBalancingConfig[0][string(BalancerRoundRobin)]; !ok {
+		t.Errorf("expected loadBalancingConfig to use %q, got %v", BalancerRoundRobin, sc.LoadBalancingConfig[0])
+	}
+}
+
+func TestMethodNamesFor(t *testing.T) {
+	names := methodNamesFor([]string{"DescribeNamespace"})
+	if len(names) != 1 {
+		t.Fatalf("expected 1 name, got %d", len(names))
+	}
+	if names[0].Service != workflowServiceName {
+		t.Errorf("expected service %q, got %q", workflowServiceName, names[0].Service)
+	}
+	if names[0].Method != "DescribeNamespace" {
+		t.Errorf("expected method %q, got %q", "DescribeNamespace", names[0].Method)
+	}
+}
+
+func containsMethod(names []methodName, method string) bool {
+	for _, n := range names {
+		if n.Method == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/spiffetls/tlsconfig"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+)
+
+// TargetIdentity is the expected server identity for a Dial target: the
+// SPIFFE ID its certificate must present, and optionally a TLS ServerName
+// override for when the target string isn't itself a usable SNI value.
+// UI -> Frontend and UI -> Auth service commonly live behind different SANs
+// even when both are fetched through the same SPIFFE Workload API socket.
+type TargetIdentity struct {
+	// ExpectedID is the SPIFFE ID the peer certificate must present. This is
+	// required: without it, any workload holding a valid SVID from a trusted
+	// trust domain could impersonate the target.
+	ExpectedID spiffeid.ID
+	// ServerName optionally overrides the TLS ServerName/SNI sent to the target.
+	ServerName string
+}
+
+// TargetIdentities maps a Dial target to its expected SPIFFE identity.
+type TargetIdentities map[string]TargetIdentity
+
+// SpiffeTLSConfigProvider is a TLSConfigProvider backed by SPIFFE X.509
+// SVIDs fetched from a Workload API (e.g. a SPIRE agent), rotating client
+// certificates automatically as they near expiry. Construct one with
+// NewSpiffeTLSConfigProvider; it must be closed with Close when no longer
+// needed to release the Workload API watch.
+type SpiffeTLSConfigProvider struct {
+	source      *workloadapi.X509Source
+	trustDomain spiffeid.TrustDomain
+	identities  TargetIdentities
+}
+
+// NewSpiffeTLSConfigProvider connects to the Workload API at socketPath
+// (pass "" to use the SPIFFE_ENDPOINT_SOCKET environment variable) and
+// returns a provider that keeps its X.509 SVID up to date for the lifetime
+// of the process. trustDomain bounds GetTLSConfig's default authorization
+// (see its doc comment); identities supplies the per-target expected
+// SPIFFE ID that GetTLSConfigFor authorizes against.
+func NewSpiffeTLSConfigProvider(ctx context.Context, socketPath string, trustDomain spiffeid.TrustDomain, identities TargetIdentities) (*SpiffeTLSConfigProvider, error) {
+	var opts []workloadapi.X509SourceOption
+	if socketPath != "" {
+		opts = append(opts, workloadapi.WithClientOptions(workloadapi.WithAddr(socketPath)))
+	}
+
+	source, err := workloadapi.NewX509Source(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SPIFFE X.509 source: %w", err)
+	}
+
+	return &SpiffeTLSConfigProvider{
+		source:      source,
+		trustDomain: trustDomain,
+		identities:  identities,
+	}, nil
+}
+
+// GetTLSConfig returns a TLS config with no per-target identity check beyond
+// membership in trustDomain. Prefer GetTLSConfigFor, which pins the peer to
+// its exact expected SPIFFE ID; this exists only for callers that dial
+// without going through a known target (e.g. GetTLSConfig's TLSConfigProvider
+// contract, which doesn't take a target).
+func (p *SpiffeTLSConfigProvider) GetTLSConfig() (*tls.Config, error) {
+	return tlsconfig.MTLSClientConfig(p.source, p.source, tlsconfig.AuthorizeMemberOf(p.trustDomain)), nil
+}
+
+// GetTLSConfigFor returns a TLS config for target, authorizing the peer only
+// if it presents the exact SPIFFE ID registered for target in
+// TargetIdentities, and overriding ServerName when one is configured. A
+// target with no registered identity falls back to GetTLSConfig's
+// trust-domain-wide check. The returned config's GetClientCertificate always
+// pulls the current SVID from the Workload API watch, so certificates rotate
+// transparently before they expire.
+func (p *SpiffeTLSConfigProvider) GetTLSConfigFor(target string) (*tls.Config, error) {
+	identity, ok := p.identities[target]
+	if !ok {
+		return p.GetTLSConfig()
+	}
+
+	cfg := tlsconfig.MTLSClientConfig(p.source, p.source, tlsconfig.AuthorizeID(identity.ExpectedID))
+	if identity.ServerName != "" {
+		cfg.ServerName = identity.ServerName
+	}
+	return cfg, nil
+}
+
+// Close releases the underlying Workload API watch.
+func (p *SpiffeTLSConfigProvider) Close() error {
+	return p.source.Close()
+}
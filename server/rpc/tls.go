@@ -0,0 +1,50 @@
+// The MIT License
+//
+// Copyright (c) 2020 Temporal Technologies Inc.  All rights reserved.
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import "crypto/tls"
+
+// TLSConfigProvider supplies the *tls.Config used to secure outbound gRPC
+// connections made by RPCFactory.
+type TLSConfigProvider interface {
+	// GetTLSConfig returns the TLS config used when no per-target override
+	// applies.
+	GetTLSConfig() (*tls.Config, error)
+	// GetTLSConfigFor returns the TLS config for a specific target, letting
+	// implementations vary ServerName or client certificates per destination
+	// (e.g. UI -> Frontend vs UI -> Auth service can present different SANs).
+	// Implementations that have no per-target behavior may return the same
+	// result as GetTLSConfig for every target.
+	GetTLSConfigFor(target string) (*tls.Config, error)
+}
+
+// GetTLSConfigFor returns the TLS config for target, delegating to the
+// configured TLSConfigProvider. It returns (nil, nil) when no provider is
+// configured, which Dial treats as "use an insecure connection".
+func (d *RPCFactory) GetTLSConfigFor(target string) (*tls.Config, error) {
+	if d.tlsFactory != nil {
+		return d.tlsFactory.GetTLSConfigFor(target)
+	}
+
+	return nil, nil
+}